@@ -0,0 +1,176 @@
+package batch
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/chfanghr/makeepub/encoding"
+	"github.com/chfanghr/makeepub/parser"
+	"github.com/chfanghr/makeepub/render"
+)
+
+// Defaults bundles the settings a BookSpec falls back to when it
+// doesn't override them itself.
+type Defaults struct {
+	Grammar  parser.Grammar
+	Encoding encoding.Name
+	Strict   bool
+	Formats  []string
+	Cover    string
+	Font     string
+	Jobs     int
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func resolveGrammar(name string, fallback parser.Grammar) (parser.Grammar, error) {
+	if name == "" {
+		return fallback, nil
+	}
+	if g, ok := parser.Builtins[name]; ok {
+		return g, nil
+	}
+	g, err := parser.LoadGrammar(name)
+	if err != nil {
+		return parser.Grammar{}, err
+	}
+	return *g, nil
+}
+
+// BuildOne converts a single BookSpec to every format in its effective
+// format list, applying d for anything the spec doesn't override.
+func BuildOne(spec BookSpec, d Defaults) error {
+	grammar, err := resolveGrammar(spec.Grammar, d.Grammar)
+	if err != nil {
+		return fmt.Errorf("batch: %s: %w", spec.Source, err)
+	}
+
+	p, err := parser.New(grammar, parser.WithStrict(d.Strict))
+	if err != nil {
+		return fmt.Errorf("batch: %s: %w", spec.Source, err)
+	}
+
+	file, err := os.Open(spec.Source)
+	if err != nil {
+		return fmt.Errorf("batch: %s: %w", spec.Source, err)
+	}
+	defer file.Close()
+
+	enc := d.Encoding
+	if spec.Encoding != "" {
+		enc = encoding.Name(spec.Encoding)
+	}
+	src, err := encoding.Wrap(enc, file)
+	if err != nil {
+		return fmt.Errorf("batch: %s: %w", spec.Source, err)
+	}
+
+	n, report, err := p.Parse(src)
+	if err != nil {
+		return fmt.Errorf("batch: %s: %w", spec.Source, err)
+	}
+	if report.HasErrors() {
+		log.Printf("batch: %s: %d parse issue(s) found", spec.Source, len(report.Errors))
+	}
+
+	if spec.Title != "" {
+		n.Title = spec.Title
+	}
+	if spec.Author != "" {
+		n.Author = spec.Author
+	}
+	if n.Title == "" {
+		n.Title = strings.TrimSuffix(filepath.Base(spec.Source), filepath.Ext(spec.Source))
+	}
+	if spec.Series != "" {
+		n.Title = spec.Series + " - " + n.Title
+	}
+
+	out := spec.Output
+	if out == "" {
+		out = n.Title
+	}
+
+	opts := render.Options{
+		Cover: firstNonEmpty(spec.Cover, d.Cover),
+		Font:  firstNonEmpty(spec.Font, d.Font),
+	}
+
+	formats := d.Formats
+	if spec.Formats != "" {
+		formats = strings.Split(spec.Formats, ",")
+	}
+
+	for _, format := range formats {
+		format = strings.TrimSpace(format)
+		r, ok := render.Renderers[format]
+		if !ok {
+			return fmt.Errorf("batch: %s: unknown format %q", spec.Source, format)
+		}
+		if err := r.Render(n, out, opts); err != nil {
+			return fmt.Errorf("batch: %s: rendering %s: %w", spec.Source, format, err)
+		}
+	}
+
+	return nil
+}
+
+// BuildAll runs BuildOne over specs, bounded by d.Jobs concurrent
+// builds, and returns one error per spec (nil entries mean success).
+func BuildAll(specs []BookSpec, d Defaults) []error {
+	jobs := d.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	sem := make(chan struct{}, jobs)
+	errs := make([]error, len(specs))
+
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, spec BookSpec) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = BuildOne(spec, d)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// BuildDir converts every .txt file directly inside dir.
+func BuildDir(dir string, d Defaults) []error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []error{fmt.Errorf("batch: reading dir %s: %w", dir, err)}
+	}
+
+	var specs []BookSpec
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+		source := filepath.Join(dir, entry.Name())
+		// Default to the source filename, not the parsed title: two
+		// files can share a title (or have none), which would make
+		// concurrent BuildAll jobs clobber each other's output.
+		output := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		specs = append(specs, BookSpec{Source: source, Output: output})
+	}
+
+	return BuildAll(specs, d)
+}
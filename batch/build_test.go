@@ -0,0 +1,46 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chfanghr/makeepub/parser"
+)
+
+func TestBuildDirConvertsEveryTxtFile(t *testing.T) {
+	dir := t.TempDir()
+	src := "《测试》\n第1章 开始\n　　内容。\n"
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ignored.md"), []byte("not a novel"), 0o644); err != nil {
+		t.Fatalf("writing ignored.md: %v", err)
+	}
+
+	outDir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(outDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	errs := BuildDir(dir, Defaults{
+		Grammar: parser.Builtins[parser.DefaultGrammarName],
+		Formats: []string{"epub"},
+		Jobs:    2,
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("BuildDir: %v", err)
+		}
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(errs))
+	}
+}
@@ -0,0 +1,51 @@
+// Package batch drives makeepub over more than one source file: a
+// directory of .txt files, an explicit manifest listing books with
+// per-book overrides, or a watched directory rebuilt as sources change.
+package batch
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BookSpec describes one book within a Manifest: its source text plus
+// any per-book overrides of the build's defaults.
+type BookSpec struct {
+	Source   string `yaml:"source"`
+	Title    string `yaml:"title,omitempty"`
+	Author   string `yaml:"author,omitempty"`
+	Series   string `yaml:"series,omitempty"`
+	Cover    string `yaml:"cover,omitempty"`
+	Font     string `yaml:"font,omitempty"`
+	Grammar  string `yaml:"grammar,omitempty"`
+	Encoding string `yaml:"encoding,omitempty"`
+	Output   string `yaml:"output,omitempty"`
+	Formats  string `yaml:"formats,omitempty"`
+}
+
+// Manifest is the single source of truth for a reproducible batch
+// build: every book to produce, and how.
+type Manifest struct {
+	Books []BookSpec `yaml:"books"`
+}
+
+// LoadManifest reads a Manifest from a YAML file, as passed to
+// `makeepub build -f manifest.yaml`.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("batch: reading manifest %s: %w", path, err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("batch: parsing manifest %s: %w", path, err)
+	}
+	if len(m.Books) == 0 {
+		return nil, fmt.Errorf("batch: manifest %s has no books", path)
+	}
+
+	return &m, nil
+}
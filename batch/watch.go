@@ -0,0 +1,51 @@
+package batch
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch rebuilds dir's .txt sources as they change, applying d to each
+// one, until stop is closed or the watcher itself fails.
+func Watch(dir string, d Defaults, stop <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("batch: watch: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("batch: watch: %w", err)
+	}
+
+	log.Printf("watching %s for changes...", dir)
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Ext(event.Name) != ".txt" {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			log.Printf("rebuilding %s...", event.Name)
+			if err := BuildOne(BookSpec{Source: event.Name}, d); err != nil {
+				log.Printf("error: %v", err)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("watch error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package encoding wraps a raw source reader with transcoding and
+// normalization, so the parser only ever sees well-formed, NFC-normal
+// UTF-8 with ASCII digits, whether the source .txt is GB18030, GBK,
+// Big5, UTF-16, or UTF-8 with or without a BOM. Auto does not detect
+// Big5; such sources need the Big5 name.
+package encoding
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Name identifies a source encoding, as passed via --encoding.
+type Name string
+
+const (
+	// Auto sniffs the first 4KB of the source to guess its encoding.
+	Auto Name = "auto"
+	UTF8 Name = "utf8"
+	GBK  Name = "gbk"
+	Big5 Name = "big5"
+)
+
+const sniffLen = 4096
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Wrap returns a reader over r that transcodes to UTF-8 per name (or, for
+// Auto, per a 4KB sniff of r) and then applies NFC normalization and a
+// full-width-digit fold, so e.g. "１２３章" and "123章" parse identically.
+// Other full-width forms (CJK punctuation like "：" and "　") are left
+// alone, since grammars match against them directly.
+func Wrap(name Name, r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	switch name {
+	case "", Auto:
+		transcoded, err := detectAndTranscode(br)
+		if err != nil {
+			return nil, err
+		}
+		return normalize(transcoded), nil
+	case UTF8:
+		return normalize(stripBOM(br)), nil
+	case GBK:
+		return normalize(transform.NewReader(br, simplifiedchinese.GBK.NewDecoder())), nil
+	case Big5:
+		return normalize(transform.NewReader(br, traditionalchinese.Big5.NewDecoder())), nil
+	default:
+		return nil, fmt.Errorf("encoding: unknown encoding %q", name)
+	}
+}
+
+// detectAndTranscode sniffs the first 4KB of br and returns a reader
+// transcoding the whole stream to UTF-8. A BOM or valid UTF-8 is taken
+// as such (a UTF-8 BOM is dropped); anything charset.DetermineEncoding
+// would otherwise read as windows-1252 is decoded as GB18030, a superset
+// of GBK. Big5 is not detected.
+func detectAndTranscode(br *bufio.Reader) (io.Reader, error) {
+	sniff, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("encoding: sniffing source: %w", err)
+	}
+
+	enc, name, _ := charset.DetermineEncoding(sniff, "")
+	switch name {
+	case "utf-8":
+		return stripBOM(br), nil
+	case "windows-1252":
+		enc = simplifiedchinese.GB18030
+	}
+	return transform.NewReader(br, enc.NewDecoder()), nil
+}
+
+func stripBOM(br *bufio.Reader) io.Reader {
+	if bom, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	return br
+}
+
+func normalize(r io.Reader) io.Reader {
+	return transform.NewReader(r, transform.Chain(norm.NFC, runes.Map(foldDigit)))
+}
+
+// foldDigit maps a full-width digit (０-９) to its ASCII equivalent and
+// leaves every other rune untouched.
+func foldDigit(r rune) rune {
+	if r >= '０' && r <= '９' {
+		return r - '０' + '0'
+	}
+	return r
+}
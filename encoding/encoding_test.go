@@ -0,0 +1,83 @@
+package encoding
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// These golden files all encode the same Chinese-novel snippet under the
+// encoding the filename advertises (the Big5 one in traditional
+// characters, the others in simplified). Wrap should bring every one of
+// them back to normalized UTF-8 equal to its want string.
+func TestWrapGoldenFiles(t *testing.T) {
+	cases := []struct {
+		file string
+		name Name
+		want string
+	}{
+		{"sample_utf8.txt", UTF8, "《测试小说》 - 作者：张三\n第1章 开始\n　　这是内容。\n第二章 继续\n　　这是第二段。\n"},
+		{"sample_utf8bom.txt", UTF8, "《测试小说》 - 作者：张三\n第1章 开始\n　　这是内容。\n第二章 继续\n　　这是第二段。\n"},
+		{"sample_gbk.txt", GBK, "《测试小说》 - 作者：张三\n第1章 开始\n　　这是内容。\n第二章 继续\n　　这是第二段。\n"},
+		{"sample_big5.txt", Big5, "《測試小說》 - 作者：張三\n第1章 開始\n　　這是內容。\n第二章 繼續\n　　這是第二段。\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.name)+"/"+tc.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tc.file))
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			defer f.Close()
+
+			r, err := Wrap(tc.name, f)
+			if err != nil {
+				t.Fatalf("Wrap: %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWrapAutoDetectsGBK(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "sample_gbk.txt"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	r, err := Wrap(Auto, f)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	want := "《测试小说》 - 作者：张三\n第1章 开始\n　　这是内容。\n第二章 继续\n　　这是第二段。\n"
+	if string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWrapFoldsFullWidthDigits(t *testing.T) {
+	r, err := Wrap(UTF8, strings.NewReader("第１２３章 标题\n"))
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "第123章 标题\n" {
+		t.Fatalf("got %q, want full-width digits folded to ASCII", got)
+	}
+}
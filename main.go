@@ -1,271 +1,282 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"github.com/bmaupin/go-epub"
-	"github.com/chfanghr/chinese_number"
 	"io"
 	"log"
 	"os"
-	"regexp"
-	"sort"
-	"strconv"
-)
-
-type paraHead struct {
-	id    int
-	title string
-}
-
-func (h paraHead) String() string {
-	if h.id != -1 {
-		return fmt.Sprintf("%d %s", h.id, h.title)
-	}
-	return h.title
-}
+	"os/signal"
+	"runtime"
+	"strings"
 
-type para struct {
-	paraHead
-	lines []string
-}
+	"github.com/bmaupin/go-epub"
 
-type paras []para
+	"github.com/chfanghr/makeepub/batch"
+	"github.com/chfanghr/makeepub/encoding"
+	"github.com/chfanghr/makeepub/parser"
+	"github.com/chfanghr/makeepub/pipeline"
+	"github.com/chfanghr/makeepub/render"
+)
 
-func (p paras) Len() int {
-	return len(p)
-}
+func loadGrammar(dialect, grammarPath string) (parser.Grammar, error) {
+	if grammarPath != "" {
+		g, err := parser.LoadGrammar(grammarPath)
+		if err != nil {
+			return parser.Grammar{}, err
+		}
+		return *g, nil
+	}
 
-func (p paras) Less(i, j int) bool {
-	return p[i].id < p[j].id
+	g, ok := parser.Builtins[dialect]
+	if !ok {
+		return parser.Grammar{}, fmt.Errorf("unknown grammar dialect %q", dialect)
+	}
+	return g, nil
 }
 
-func (p paras) Swap(i, j int) {
-	tmp := p[j]
-	p[j] = p[i]
-	p[i] = tmp
+func safeClose(closer io.Closer) {
+	if err := closer.Close(); err != nil {
+		log.Panicf("error: %v", err)
+	}
 }
 
-type novelHead struct {
-	title  string
-	author string
+func parseFormats(formats string) ([]string, error) {
+	var out []string
+	for _, f := range strings.Split(formats, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if _, ok := render.Renderers[f]; !ok {
+			return nil, fmt.Errorf("unknown format %q", f)
+		}
+		out = append(out, f)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no formats given")
+	}
+	return out, nil
 }
 
-type novel struct {
-	novelHead
-	paras paras
+func titleOrFallback(title, path string) string {
+	if title == "" {
+		log.Println("novel doesn't have a title, use filename instead")
+		return path
+	}
+	return title
 }
 
-func (n novel) toEpub() (*epub.Epub, error) {
-	sort.Sort(n.paras)
-
-	e := epub.NewEpub(n.title)
-
-	e.SetAuthor(n.author)
-
-	for _, para := range n.paras {
-		sectionBody := "<h1>" + para.paraHead.String() + "</h1>\n<p></p>\n"
-		for _, line := range para.lines {
-			sectionBody += "<p>" + line + "</p>\n"
-		}
-		if _, err := e.AddSection(sectionBody, para.paraHead.String(), "", ""); err != nil {
-			return nil, err
+func reportErrors(errs []error) {
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			log.Println(err)
+			failed++
 		}
 	}
-
-	return e, nil
+	if failed > 0 {
+		log.Panicf("error: %d of %d book(s) failed", failed, len(errs))
+	}
 }
 
-func isArabic(ch rune) bool {
-	return '0' <= ch && ch <= '9'
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
 }
 
-func isDigit(ch rune) bool {
-	if '0' <= ch && ch <= '9' {
-		return true
+// printReport writes a non-empty ParseReport to stdout (json) or stderr
+// (text, colored if it's a terminal), as chosen by --report.
+func printReport(report *parser.ParseReport, format string) {
+	if !report.HasErrors() {
+		return
 	}
-
-	switch ch {
-	case '零', '一', '二', '三', '四', '五',
-		'六', '七', '八', '九', '十',
-		'百', '千', '万':
-		return true
+	var err error
+	if format == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stderr, isTerminal(os.Stderr))
+	}
+	if err != nil {
+		log.Printf("error: writing report: %v", err)
 	}
-
-	return false
 }
 
-func parseLine(line string, n *novel, lastStat bool) (isUnknown bool) {
-	if len(line) == 0 {
-		return true
+// buildSingleEpubStreaming is the fast path for the common case: one
+// source file, one epub output, no cover. It uses the concurrent
+// scan/render/write pipeline, which starts rendering chapter 1 before
+// the rest of the file has even been scanned.
+func buildSingleEpubStreaming(path string, grammar parser.Grammar, enc encoding.Name, workers int, strict bool, reportFormat string) {
+	p, err := parser.New(grammar, parser.WithStrict(strict))
+	if err != nil {
+		log.Panicf("error: %v", err)
 	}
 
-	runeLine := []rune(line)
-
-	switch runeLine[0] {
-	case '《': // novel header
-		hasRightAngleQuotationMark := false
-		var title, author []rune
-		i := 1
-
-		for ; i < len(runeLine); i++ {
-			if runeLine[i] == '》' {
-				hasRightAngleQuotationMark = true
-				break
-			}
-			title = append(title, runeLine[i])
-		}
-		if !hasRightAngleQuotationMark {
-			log.Println("novel title doesn't have matched angle quotation marks")
-			return true
-		}
+	file, err := os.Open(path)
+	if err != nil {
+		log.Panicf("error: %v", err)
+	}
+	defer safeClose(file)
 
-		if i <= len(runeLine)-7 && string(runeLine[i+1:i+7]) == " - 作者：" { // parse author
-			log.Println("novel doesn't have an author")
-			author = runeLine[i+7:]
-		}
+	src, err := encoding.Wrap(enc, file)
+	if err != nil {
+		log.Panicf("error: %v", err)
+	}
 
-		n.novelHead = novelHead{
-			title:  string(title),
-			author: string(author),
-		}
-	case ' ', '　': // parse content of paragraph
-		if lastStat {
-			log.Println("waiting for next valid header....")
-			return true
-		}
-		i := 0
-		for ; i < len(runeLine) && (runeLine[i] == ' ' || runeLine[i] == '\t' || runeLine[i] == '　'); i++ {
-		}
-		if i == len(runeLine) {
-			log.Println("empty line in content")
-			return false
-		}
-		n.paras[len(n.paras)-1].lines = append(n.paras[len(n.paras)-1].lines, string(runeLine[i:]))
-	case '第': // parse title of paragraph
-		i := 1
-
-		var runeId []rune
-
-		for ; i < len(runeLine); i++ {
-			if isDigit(runeLine[i]) {
-				runeId = append(runeId, runeLine[i])
-			} else {
-				break
-			}
-		}
+	log.Printf("processing %v...", path)
+	log.Println("parsing and rendering...")
 
-		if len(runeId) == 0 || runeLine[i] != '章' {
-			log.Println("invalid title of paragraph")
-			return true
-		}
+	e := epub.NewEpub(path)
+	head, report, err := pipeline.New(workers).Run(p, src, e)
+	printReport(report, reportFormat)
+	if err != nil {
+		log.Panicf("error: %v", err)
+	}
 
-		var err error
-		var id int
-
-		if isArabic(runeId[0]) {
-			id, err = strconv.Atoi(string(runeId))
-			if err != nil {
-				log.Println("cannot parse id of paragraph: ", err)
-				return true
-			}
-		} else {
-			id, err = chinese_number.ToArabicNumber(string(runeId))
-			if err != nil {
-				var altId []int
-				for _, r := range runeId {
-					num, err := chinese_number.ParseChineseNumberCharacter(r)
-					if err != nil {
-						log.Println("cannot parse id of paragraph: ", err)
-						return true
-					}
-					altId = append(altId, num.GetValue())
-				}
-				factor := 1
-				for i = len(altId) - 1; i >= 0; i-- {
-					id += altId[i] * factor
-					factor *= 10
-				}
-			}
-		}
+	title := titleOrFallback(head.Title, path)
+	e.SetTitle(title)
+	e.SetAuthor(head.Author)
 
-		n.paras = append(n.paras, para{
-			paraHead: paraHead{
-				id:    id,
-				title: string(runeLine[i+2:]),
-			},
-			lines: nil,
-		})
-	default:
-		if regexp.MustCompile("番外：(.*?)").MatchString(line) {
-			n.paras = append(n.paras, para{
-				paraHead: paraHead{
-					id:    -1,
-					title: line,
-				},
-				lines: nil,
-			})
-			return false
-		}
-		return true
+	log.Printf("writing %s to disk...", title+".epub")
+	if err := e.Write(title + ".epub"); err != nil {
+		log.Panicf("error: %v", err)
 	}
 
-	return false
+	log.Println("process: done")
 }
 
-func safeClose(closer io.Closer) {
-	if err := closer.Close(); err != nil {
+func runBuild(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	dialect := fs.String("dialect", parser.DefaultGrammarName, "built-in chapter grammar to use")
+	grammarPath := fs.String("grammar", "", "path to a custom grammar YAML file (overrides --dialect)")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of chapters to render concurrently")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of books to build concurrently (directory/manifest mode)")
+	formats := fs.String("format", "epub", "comma-separated output formats: epub,mobi,pdf")
+	cover := fs.String("cover", "", "path to a cover image")
+	font := fs.String("font", "", "path to a TrueType font to embed (mobi)")
+	enc := fs.String("encoding", string(encoding.Auto), "source encoding: auto|utf8|gbk|big5")
+	strict := fs.Bool("strict", false, "fail the build on any parse error instead of collecting them")
+	reportFormat := fs.String("report", "text", "parse report format: text|json")
+	manifestPath := fs.String("f", "", "path to a manifest YAML listing multiple books, each with its own overrides")
+	_ = fs.Parse(args)
+
+	outFormats, err := parseFormats(*formats)
+	if err != nil {
 		log.Panicf("error: %v", err)
 	}
-}
-
-func main() {
-	file, err := os.Open(os.Args[1])
+	grammar, err := loadGrammar(*dialect, *grammarPath)
 	if err != nil {
 		log.Panicf("error: %v", err)
 	}
-	defer safeClose(file)
 
-	scanner := bufio.NewScanner(file)
-	novel := novel{}
-	lineId := uint64(1)
+	defaults := batch.Defaults{
+		Grammar:  grammar,
+		Encoding: encoding.Name(*enc),
+		Strict:   *strict,
+		Formats:  outFormats,
+		Cover:    *cover,
+		Font:     *font,
+		Jobs:     *jobs,
+	}
 
-	log.Printf("processing %v...", os.Args[1])
-	log.Println("parsing...")
+	if *manifestPath != "" {
+		m, err := batch.LoadManifest(*manifestPath)
+		if err != nil {
+			log.Panicf("error: %v", err)
+		}
+		reportErrors(batch.BuildAll(m.Books, defaults))
+		return
+	}
 
-	lastStat := false
+	if fs.NArg() < 1 {
+		log.Panicf("error: missing source file or directory")
+	}
+	path := fs.Arg(0)
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if lastStat = parseLine(line, &novel, lastStat); lastStat {
-			log.Printf("unknown line %d: %s", lineId, line)
-		}
-		lineId++
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Panicf("error: %v", err)
+	}
+	if info.IsDir() {
+		reportErrors(batch.BuildDir(path, defaults))
+		return
 	}
 
-	log.Println("parse: done")
+	if len(outFormats) == 1 && outFormats[0] == "epub" && *cover == "" {
+		buildSingleEpubStreaming(path, grammar, defaults.Encoding, *workers, *strict, *reportFormat)
+		return
+	}
 
-	log.Println("converting to epub...")
+	if err := batch.BuildOne(batch.BookSpec{Source: path}, defaults); err != nil {
+		log.Panicf("error: %v", err)
+	}
+}
 
-	e, err := novel.toEpub()
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	dialect := fs.String("dialect", parser.DefaultGrammarName, "built-in chapter grammar to use")
+	grammarPath := fs.String("grammar", "", "path to a custom grammar YAML file (overrides --dialect)")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "has no effect in watch mode: changed files are rebuilt one at a time")
+	formats := fs.String("format", "epub", "comma-separated output formats: epub,mobi,pdf")
+	cover := fs.String("cover", "", "path to a cover image")
+	font := fs.String("font", "", "path to a TrueType font to embed (mobi)")
+	enc := fs.String("encoding", string(encoding.Auto), "source encoding: auto|utf8|gbk|big5")
+	strict := fs.Bool("strict", false, "fail a rebuild on any parse error instead of collecting them")
+	_ = fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		log.Panicf("error: missing directory to watch")
+	}
+	dir := fs.Arg(0)
 
+	outFormats, err := parseFormats(*formats)
+	if err != nil {
+		log.Panicf("error: %v", err)
+	}
+	grammar, err := loadGrammar(*dialect, *grammarPath)
 	if err != nil {
 		log.Panicf("error: %v", err)
 	}
 
-	log.Println("convert: done")
-
-	if novel.title == "" {
-		log.Println("novel doesn't have a title, use filename instead")
-		novel.title = os.Args[1]
+	defaults := batch.Defaults{
+		Grammar:  grammar,
+		Encoding: encoding.Name(*enc),
+		Strict:   *strict,
+		Formats:  outFormats,
+		Cover:    *cover,
+		Font:     *font,
+		Jobs:     *jobs,
 	}
 
-	log.Printf("writing %s to disk...", novel.title+".epub")
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	stop := make(chan struct{})
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
 
-	if err = e.Write(novel.title + ".epub"); err != nil {
+	if err := batch.Watch(dir, defaults, stop); err != nil {
 		log.Panicf("error: %v", err)
 	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Panicf("error: usage: makeepub <build|watch> ...")
+	}
 
-	log.Println("write: done")
-	log.Println("process: done")
+	switch os.Args[1] {
+	case "build":
+		runBuild(os.Args[2:])
+	case "watch":
+		runWatch(os.Args[2:])
+	default:
+		// Backward-compatible shorthand: `makeepub file.txt [flags]`
+		// behaves like `makeepub build file.txt [flags]`.
+		runBuild(os.Args[1:])
+	}
 }
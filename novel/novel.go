@@ -0,0 +1,96 @@
+// Package novel holds the in-memory representation of a parsed novel,
+// independent of where it came from (parser) or where it is going
+// (render).
+package novel
+
+import "fmt"
+
+// Kind classifies where a ParaHead belongs in reading order, independent
+// of its ID: KindBody headers (chapters, volumes) sort by ID; KindPrologue
+// and KindTrailing headers don't carry a usable ID (it's always -1) and
+// are instead pinned before or after every KindBody header, respectively.
+type Kind int
+
+const (
+	KindBody Kind = iota
+	KindPrologue
+	KindTrailing
+)
+
+// ParaHead identifies a paragraph (chapter, volume, prologue, footnote...)
+// within a novel. ID is -1 for headers that don't carry a numeric order,
+// e.g. "番外" side stories or "楔子"/"尾声" markers; Kind says where such
+// a header belongs instead. Seq is the order the header was scanned in,
+// used only to break ties between headers that compare equal otherwise
+// (e.g. two "番外" side stories), since concurrent rendering can finish
+// them in any order.
+type ParaHead struct {
+	ID    int
+	Title string
+	Kind  Kind
+	Seq   int
+}
+
+func (h ParaHead) String() string {
+	if h.ID != -1 {
+		return fmt.Sprintf("%d %s", h.ID, h.Title)
+	}
+	return h.Title
+}
+
+func (h ParaHead) rank() int {
+	switch h.Kind {
+	case KindPrologue:
+		return 0
+	case KindTrailing:
+		return 2
+	default: // KindBody
+		return 1
+	}
+}
+
+// Less reports whether h belongs before other in reading order:
+// KindPrologue headers first, then KindBody headers by ID, then
+// KindTrailing headers — each group in Seq (scan) order among itself.
+func (h ParaHead) Less(other ParaHead) bool {
+	if rh, ro := h.rank(), other.rank(); rh != ro {
+		return rh < ro
+	}
+	if h.ID != other.ID {
+		return h.ID < other.ID
+	}
+	return h.Seq < other.Seq
+}
+
+// Para is a single chapter: its header plus the lines of content that
+// belong to it.
+type Para struct {
+	ParaHead
+	Lines []string
+}
+
+type Paras []Para
+
+func (p Paras) Len() int {
+	return len(p)
+}
+
+func (p Paras) Less(i, j int) bool {
+	return p[i].ParaHead.Less(p[j].ParaHead)
+}
+
+func (p Paras) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+type NovelHead struct {
+	Title  string
+	Author string
+}
+
+// Novel is the fully parsed result of a source text: its metadata plus
+// every chapter found in it.
+type Novel struct {
+	NovelHead
+	Paras Paras
+}
@@ -0,0 +1,46 @@
+package novel
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestParasSortsPrologueBodyTrailing(t *testing.T) {
+	paras := Paras{
+		{ParaHead: ParaHead{ID: -1, Title: "番外", Kind: KindTrailing, Seq: 4}},
+		{ParaHead: ParaHead{ID: 2, Title: "第二章", Kind: KindBody, Seq: 2}},
+		{ParaHead: ParaHead{ID: -1, Title: "尾声", Kind: KindTrailing, Seq: 3}},
+		{ParaHead: ParaHead{ID: 1, Title: "第一章", Kind: KindBody, Seq: 1}},
+		{ParaHead: ParaHead{ID: -1, Title: "楔子", Kind: KindPrologue, Seq: 0}},
+	}
+
+	sort.Stable(paras)
+
+	var titles []string
+	for _, p := range paras {
+		titles = append(titles, p.Title)
+	}
+
+	want := []string{"楔子", "第一章", "第二章", "尾声", "番外"}
+	for i, title := range want {
+		if titles[i] != title {
+			t.Fatalf("unexpected order: got %v, want %v", titles, want)
+		}
+	}
+}
+
+func TestParasBreaksTiesByScanOrder(t *testing.T) {
+	// Two side stories with no other ordering signal must keep the
+	// order they were scanned in, since a concurrent pipeline can
+	// render them in any order.
+	paras := Paras{
+		{ParaHead: ParaHead{ID: -1, Title: "番外二", Kind: KindTrailing, Seq: 1}},
+		{ParaHead: ParaHead{ID: -1, Title: "番外一", Kind: KindTrailing, Seq: 0}},
+	}
+
+	sort.Stable(paras)
+
+	if paras[0].Title != "番外一" || paras[1].Title != "番外二" {
+		t.Fatalf("unexpected order: %+v", paras)
+	}
+}
@@ -0,0 +1,42 @@
+package parser
+
+// Builtin grammars cover the chapter-heading dialects most commonly seen
+// in Chinese web-novel archives, plus a plain English fallback. Select
+// one by name, or load a custom Grammar from YAML via --grammar.
+var Builtins = map[string]Grammar{
+	"zh-chapter": {
+		Name:     "zh-chapter",
+		Chapter:  `^第(?P<id>[0-9零一二三四五六七八九十百千万]+)章\s*(?P<title>.*)$`,
+		Prologue: `^楔子`,
+		Epilogue: `^尾声`,
+		Footnote: `番外：(.*?)`,
+		Title:    `^《(?P<title>[^》]+)》(?:\s*-\s*作者：(?P<author>.+))?$`,
+	},
+	"zh-volume-chapter": {
+		Name:     "zh-volume-chapter",
+		Chapter:  `^第(?P<id>[0-9零一二三四五六七八九十百千万]+)章\s*(?P<title>.*)$`,
+		Volume:   `^卷[0-9零一二三四五六七八九十百千万]+\s*第(?P<id>[0-9零一二三四五六七八九十百千万]+)章\s*(?P<title>.*)$`,
+		Prologue: `^楔子`,
+		Epilogue: `^尾声`,
+		Footnote: `番外：(.*?)`,
+		Title:    `^《(?P<title>[^》]+)》(?:\s*-\s*作者：(?P<author>.+))?$`,
+	},
+	"zh-hui": {
+		Name:     "zh-hui",
+		Chapter:  `^第(?P<id>[0-9零一二三四五六七八九十百千万]+)回\s*(?P<title>.*)$`,
+		Prologue: `^楔子`,
+		Epilogue: `^尾声`,
+		Footnote: `番外：(.*?)`,
+		Title:    `^《(?P<title>[^》]+)》(?:\s*-\s*作者：(?P<author>.+))?$`,
+	},
+	"en-chapter": {
+		Name:     "en-chapter",
+		Chapter:  `(?i)^Chapter\s+(?P<id>[0-9]+)\s*[:.-]?\s*(?P<title>.*)$`,
+		Prologue: `(?i)^Prologue\s*$`,
+		Epilogue: `(?i)^Epilogue\s*$`,
+	},
+}
+
+// DefaultGrammarName is used when neither --dialect nor --grammar is
+// given.
+const DefaultGrammarName = "zh-chapter"
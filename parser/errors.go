@@ -0,0 +1,70 @@
+package parser
+
+import "fmt"
+
+// ErrorKind classifies a single issue found while parsing a line.
+type ErrorKind int
+
+const (
+	// UnknownLine is a line that matched no header, content, or title
+	// pattern at all.
+	UnknownLine ErrorKind = iota
+	// UnmatchedQuote is a novel title line missing its closing 《》.
+	UnmatchedQuote
+	// EmptyContent is a content line that is only whitespace.
+	EmptyContent
+	// OrphanContent is a content line with no chapter open yet to
+	// attach it to.
+	OrphanContent
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case UnknownLine:
+		return "unknown line"
+	case UnmatchedQuote:
+		return "unmatched quote"
+	case EmptyContent:
+		return "empty content"
+	case OrphanContent:
+		return "content before any chapter"
+	default:
+		return "unknown error"
+	}
+}
+
+// ParseError is one issue found while scanning a line, carrying enough
+// position information for editor integrations (and --report) to point
+// at the offending column.
+type ParseError struct {
+	Line    int
+	Column  int
+	Kind    ErrorKind
+	Snippet string
+	Cause   error
+}
+
+func (e *ParseError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("line %d:%d: %s: %v", e.Line, e.Column, e.Kind, e.Cause)
+	}
+	return fmt.Sprintf("line %d:%d: %s: %q", e.Line, e.Column, e.Kind, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error { return e.Cause }
+
+// ParseReport accumulates every ParseError found over a full parse, so
+// one malformed chapter doesn't drop content or abort the whole run
+// unless --strict is set.
+type ParseReport struct {
+	Errors []*ParseError
+}
+
+func (r *ParseReport) add(e *ParseError) {
+	r.Errors = append(r.Errors, e)
+}
+
+// HasErrors reports whether anything went wrong during the parse.
+func (r *ParseReport) HasErrors() bool {
+	return r != nil && len(r.Errors) > 0
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrictFailsOnAnyError(t *testing.T) {
+	src := "《测试》\n" +
+		"this is not a recognized header\n"
+
+	p, err := New(Builtins[DefaultGrammarName], WithStrict(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, report, err := p.Parse(strings.NewReader(src)); err == nil {
+		t.Fatal("expected strict mode to return an error")
+	} else if !report.HasErrors() {
+		t.Fatal("expected the report to carry the triggering error")
+	}
+}
+
+func TestNonStrictCollectsErrors(t *testing.T) {
+	src := "《测试》\n" +
+		"this is not a recognized header\n" +
+		"第1章 开始\n　　内容。\n"
+
+	p, err := New(Builtins[DefaultGrammarName])
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	n, report, err := p.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Kind != UnknownLine {
+		t.Fatalf("expected one UnknownLine error, got %+v", report.Errors)
+	}
+	if len(n.Paras) != 1 {
+		t.Fatalf("expected chapter 1 to still be parsed, got %+v", n.Paras)
+	}
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Grammar describes the set of patterns a Parser uses to recognize the
+// structure of a source text: chapter and volume headers, prologue and
+// epilogue markers, footnotes, and the novel's own title line.
+//
+// Every pattern is a Go regexp. Chapter and Volume must contain a named
+// group "id" (the chapter/volume number, Arabic or Chinese numerals) and
+// may contain a named group "title" (the text following the number).
+// Title, if set, must contain a named group "title" and may contain a
+// named group "author" (see parser.New).
+type Grammar struct {
+	Name     string `yaml:"name"`
+	Chapter  string `yaml:"chapter"`
+	Volume   string `yaml:"volume,omitempty"`
+	Prologue string `yaml:"prologue,omitempty"`
+	Epilogue string `yaml:"epilogue,omitempty"`
+	Footnote string `yaml:"footnote,omitempty"`
+	Title    string `yaml:"title,omitempty"`
+}
+
+// LoadGrammar reads a Grammar from a YAML file, as passed via --grammar.
+func LoadGrammar(path string) (*Grammar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("parser: reading grammar %s: %w", path, err)
+	}
+
+	var g Grammar
+	if err := yaml.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("parser: parsing grammar %s: %w", path, err)
+	}
+	if g.Chapter == "" {
+		return nil, fmt.Errorf("parser: grammar %s has no chapter pattern", path)
+	}
+
+	return &g, nil
+}
+
+// compiled holds the regexes built from a Grammar, ready to match lines.
+// It doesn't hold Title: that pattern is matched against the novel's own
+// header line by Parser directly (see parser.New), not by ChapterMatcher.
+type compiled struct {
+	grammar  Grammar
+	chapter  *regexp.Regexp
+	volume   *regexp.Regexp
+	prologue *regexp.Regexp
+	epilogue *regexp.Regexp
+	footnote *regexp.Regexp
+}
+
+func mustCompileOptional(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// Compile turns a Grammar into a ChapterMatcher, validating every pattern
+// it uses up front so bad grammars fail fast instead of silently matching
+// nothing. Title is not compiled here; parser.New validates it.
+func (g Grammar) Compile() (ChapterMatcher, error) {
+	c := &compiled{grammar: g}
+
+	var err error
+	if c.chapter, err = regexp.Compile(g.Chapter); err != nil {
+		return nil, fmt.Errorf("parser: grammar %s: chapter pattern: %w", g.Name, err)
+	}
+	if c.volume, err = mustCompileOptional(g.Volume); err != nil {
+		return nil, fmt.Errorf("parser: grammar %s: volume pattern: %w", g.Name, err)
+	}
+	if c.prologue, err = mustCompileOptional(g.Prologue); err != nil {
+		return nil, fmt.Errorf("parser: grammar %s: prologue pattern: %w", g.Name, err)
+	}
+	if c.epilogue, err = mustCompileOptional(g.Epilogue); err != nil {
+		return nil, fmt.Errorf("parser: grammar %s: epilogue pattern: %w", g.Name, err)
+	}
+	if c.footnote, err = mustCompileOptional(g.Footnote); err != nil {
+		return nil, fmt.Errorf("parser: grammar %s: footnote pattern: %w", g.Name, err)
+	}
+
+	return c, nil
+}
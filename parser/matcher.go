@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/chfanghr/chinese_number"
+
+	"github.com/chfanghr/makeepub/novel"
+)
+
+// HeaderKind classifies what a matched line represents within a novel.
+type HeaderKind int
+
+const (
+	// KindChapter is a regular, numbered chapter.
+	KindChapter HeaderKind = iota
+	// KindVolume is a volume/book-level header that precedes a chapter
+	// number on the same line (e.g. "卷一 第一章").
+	KindVolume
+	// KindPrologue is an unnumbered prologue marker (e.g. "楔子").
+	KindPrologue
+	// KindEpilogue is an unnumbered epilogue marker (e.g. "尾声").
+	KindEpilogue
+	// KindSideStory is an unnumbered side-story marker (e.g. "番外").
+	KindSideStory
+)
+
+// NovelKind maps a HeaderKind to the novel.Kind that controls where it
+// sorts: prologues go before every chapter, epilogues and side stories
+// go after every chapter, and everything else sorts by ID among the
+// chapters.
+func (k HeaderKind) NovelKind() novel.Kind {
+	switch k {
+	case KindPrologue:
+		return novel.KindPrologue
+	case KindEpilogue, KindSideStory:
+		return novel.KindTrailing
+	default: // KindChapter, KindVolume
+		return novel.KindBody
+	}
+}
+
+// Header is the result of a successful ChapterMatcher.Match: the kind of
+// header found, its numeric ID (-1 if it has none), and its title text.
+type Header struct {
+	Kind  HeaderKind
+	ID    int
+	Title string
+}
+
+// ChapterMatcher recognizes chapter/volume/prologue/epilogue headers in a
+// line of source text. Implementations are produced by Grammar.Compile,
+// which makes the set of recognized dialects data-driven instead of a
+// hard-coded switch.
+type ChapterMatcher interface {
+	Match(line string) (Header, bool)
+}
+
+func (c *compiled) Match(line string) (Header, bool) {
+	if c.volume != nil {
+		if h, ok := matchNumbered(c.volume, line); ok {
+			h.Kind = KindVolume
+			return h, true
+		}
+	}
+	if h, ok := matchNumbered(c.chapter, line); ok {
+		h.Kind = KindChapter
+		return h, true
+	}
+	if c.prologue != nil && c.prologue.MatchString(line) {
+		return Header{Kind: KindPrologue, ID: -1, Title: line}, true
+	}
+	if c.epilogue != nil && c.epilogue.MatchString(line) {
+		return Header{Kind: KindEpilogue, ID: -1, Title: line}, true
+	}
+	if c.footnote != nil && c.footnote.MatchString(line) {
+		return Header{Kind: KindSideStory, ID: -1, Title: line}, true
+	}
+
+	return Header{}, false
+}
+
+// matchNumbered applies re to line and, if it matches, resolves the named
+// "id" group (Arabic or Chinese numerals) and "title" group into a Header.
+func matchNumbered(re *regexp.Regexp, line string) (Header, bool) {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return Header{}, false
+	}
+
+	names := re.SubexpNames()
+	var rawID, title string
+	for i, name := range names {
+		switch name {
+		case "id":
+			rawID = m[i]
+		case "title":
+			title = m[i]
+		}
+	}
+	if rawID == "" {
+		return Header{}, false
+	}
+
+	id, err := parseID(rawID)
+	if err != nil {
+		return Header{}, false
+	}
+
+	return Header{ID: id, Title: title}, true
+}
+
+func isArabic(ch rune) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+// parseID turns a chapter/volume number, either Arabic ("123") or
+// Chinese ("一百二十三" / "一二三"), into an int.
+func parseID(raw string) (int, error) {
+	runes := []rune(raw)
+	if isArabic(runes[0]) {
+		return strconv.Atoi(raw)
+	}
+
+	id, err := chinese_number.ToArabicNumber(raw)
+	if err == nil {
+		return id, nil
+	}
+
+	// Fall back to reading the numerals digit-by-digit, for strings like
+	// "一二三" that aren't valid compound Chinese numerals.
+	var digits []int
+	for _, r := range runes {
+		num, err := chinese_number.ParseChineseNumberCharacter(r)
+		if err != nil {
+			return 0, err
+		}
+		digits = append(digits, num.GetValue())
+	}
+
+	id = 0
+	factor := 1
+	for i := len(digits) - 1; i >= 0; i-- {
+		id += digits[i] * factor
+		factor *= 10
+	}
+	return id, nil
+}
@@ -0,0 +1,78 @@
+// Package parser turns novel source text into a novel.Novel. Recognizing
+// chapter/volume/prologue headers is pluggable via ChapterMatcher, so new
+// dialects can be added without touching the scanning loop below.
+package parser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/chfanghr/makeepub/novel"
+)
+
+var defaultTitlePattern = regexp.MustCompile(`^《(?P<title>[^》]+)》(?:\s*-\s*作者：(?P<author>.+))?$`)
+
+// Parser reads a source text line by line and assembles a novel.Novel,
+// using a ChapterMatcher to recognize headers.
+type Parser struct {
+	matcher ChapterMatcher
+	title   *regexp.Regexp
+	strict  bool
+}
+
+// Option configures a Parser at construction time.
+type Option func(*Parser)
+
+// WithStrict makes ParseStream/Parse return an error, once the whole
+// source has been scanned, if the report has any entries, instead of
+// only collecting them.
+func WithStrict(strict bool) Option {
+	return func(p *Parser) { p.strict = strict }
+}
+
+// New builds a Parser from a Grammar.
+func New(g Grammar, opts ...Option) (*Parser, error) {
+	matcher, err := g.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	title := defaultTitlePattern
+	if g.Title != "" {
+		title, err = regexp.Compile(g.Title)
+		if err != nil {
+			return nil, fmt.Errorf("parser: grammar %s: title pattern: %w", g.Name, err)
+		}
+	}
+
+	p := &Parser{matcher: matcher, title: title}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// Parse reads every line of r and returns the resulting novel plus a
+// report of every issue found along the way. It is a thin, whole-in-
+// memory wrapper around ParseStream for callers (and tests) that don't
+// need a streaming pipeline.
+func (p *Parser) Parse(r io.Reader) (*novel.Novel, *ParseReport, error) {
+	events, done := p.ParseStream(r)
+
+	n := &novel.Novel{}
+	for ev := range events {
+		if ev.Head != nil {
+			n.NovelHead = *ev.Head
+		}
+		if ev.Para != nil {
+			n.Paras = append(n.Paras, *ev.Para)
+		}
+	}
+
+	result := <-done
+	if result.Err != nil {
+		return nil, result.Report, result.Err
+	}
+	return n, result.Report, nil
+}
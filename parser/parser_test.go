@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseZhChapter(t *testing.T) {
+	src := "《测试小说》 - 作者：张三\n" +
+		"第1章 开始\n" +
+		"　　这是第一段。\n" +
+		"第二章 继续\n" +
+		"　　这是第二段。\n"
+
+	p, err := New(Builtins[DefaultGrammarName])
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	n, report, err := p.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if report.HasErrors() {
+		t.Fatalf("unexpected parse errors: %v", report.Errors)
+	}
+
+	if n.Title != "测试小说" || n.Author != "张三" {
+		t.Fatalf("unexpected novel head: %+v", n.NovelHead)
+	}
+	if len(n.Paras) != 2 {
+		t.Fatalf("expected 2 paras, got %d", len(n.Paras))
+	}
+	if n.Paras[0].ID != 1 || n.Paras[1].ID != 2 {
+		t.Fatalf("unexpected chapter ids: %d, %d", n.Paras[0].ID, n.Paras[1].ID)
+	}
+	if n.Paras[1].Title != "继续" {
+		t.Fatalf("unexpected chapter title: %q", n.Paras[1].Title)
+	}
+}
+
+func TestParseEnChapter(t *testing.T) {
+	src := "Chapter 1: A Beginning\n" +
+		"    Some content.\n"
+
+	p, err := New(Builtins["en-chapter"])
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	n, _, err := p.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(n.Paras) != 1 || n.Paras[0].ID != 1 {
+		t.Fatalf("unexpected paras: %+v", n.Paras)
+	}
+}
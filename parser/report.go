@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonError is ParseError's wire shape for --report json: Cause is
+// flattened to a string since errors don't marshal on their own.
+type jsonError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Kind    string `json:"kind"`
+	Snippet string `json:"snippet,omitempty"`
+	Cause   string `json:"cause,omitempty"`
+}
+
+// WriteJSON writes the report as a JSON array of errors, for machine
+// consumption (e.g. editor integrations).
+func (r *ParseReport) WriteJSON(w io.Writer) error {
+	out := make([]jsonError, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		je := jsonError{Line: e.Line, Column: e.Column, Kind: e.Kind.String(), Snippet: e.Snippet}
+		if e.Cause != nil {
+			je.Cause = e.Cause.Error()
+		}
+		out = append(out, je)
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+const (
+	ansiRed   = "\x1b[31m"
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+// WriteText writes each error as a "line L:C kind" line followed by its
+// snippet, if any, optionally colored for a terminal; when colored, a
+// caret under the snippet points at the offending column.
+func (r *ParseReport) WriteText(w io.Writer, colored bool) error {
+	for _, e := range r.Errors {
+		if colored {
+			if _, err := fmt.Fprintf(w, "%sline %d:%d%s %s%s%s\n", ansiDim, e.Line, e.Column, ansiReset, ansiRed, e.Kind, ansiReset); err != nil {
+				return err
+			}
+		} else if _, err := fmt.Fprintf(w, "line %d:%d %s\n", e.Line, e.Column, e.Kind); err != nil {
+			return err
+		}
+		if e.Snippet != "" {
+			if _, err := fmt.Fprintf(w, "    %s\n", e.Snippet); err != nil {
+				return err
+			}
+			if colored {
+				if _, err := fmt.Fprintf(w, "    %s%s^%s\n", ansiRed, columnPadding(e.Column), ansiReset); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func columnPadding(column int) string {
+	if column <= 1 {
+		return ""
+	}
+	b := make([]byte, column-1)
+	for i := range b {
+		b[i] = ' '
+	}
+	return string(b)
+}
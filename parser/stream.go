@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/chfanghr/makeepub/novel"
+)
+
+// Event is one item emitted by ParseStream: either the novel's head
+// (title/author), seen once when its header line is parsed, or a
+// completed Para, sent as soon as the next header (or EOF) closes it
+// out. Exactly one of the two fields is set.
+type Event struct {
+	Head *novel.NovelHead
+	Para *novel.Para
+}
+
+// StreamResult is sent once on ParseStream's done channel, after events
+// closes: the accumulated ParseReport, and a non-nil Err if the scan
+// itself failed (I/O error) or --strict was set and the report has any
+// errors in it.
+type StreamResult struct {
+	Report *ParseReport
+	Err    error
+}
+
+// ParseStream scans r on its own goroutine and emits Events as soon as
+// each chapter is complete, instead of building the whole novel in
+// memory first. This lets a pipeline start rendering chapter 1 while
+// chapter 50 is still being scanned.
+func (p *Parser) ParseStream(r io.Reader) (<-chan Event, <-chan StreamResult) {
+	events := make(chan Event)
+	done := make(chan StreamResult, 1)
+
+	go func() {
+		defer close(events)
+		defer close(done)
+
+		report := &ParseReport{}
+		scanner := bufio.NewScanner(r)
+		var current *novel.Para
+		lineNum := 1
+		lastUnknown := false
+		seq := 0
+
+		emit := func() {
+			if current != nil {
+				events <- Event{Para: current}
+				current = nil
+			}
+		}
+
+		for scanner.Scan() {
+			line := scanner.Text()
+			perr, head, newPara := p.parseStreamLine(line, current, lastUnknown, lineNum)
+			if perr != nil {
+				report.add(perr)
+			}
+			if head != nil {
+				events <- Event{Head: head}
+			}
+			if newPara != nil {
+				// Seq records scan order, so sorting can break ties
+				// between headers the grammar doesn't otherwise order
+				// (e.g. two "番外" side stories) deterministically, even
+				// though a concurrent pipeline may render them out of
+				// that order.
+				newPara.Seq = seq
+				seq++
+				emit()
+				current = newPara
+			}
+			lastUnknown = perr != nil
+			lineNum++
+		}
+		emit()
+
+		if err := scanner.Err(); err != nil {
+			done <- StreamResult{Report: report, Err: err}
+			return
+		}
+		if p.strict && report.HasErrors() {
+			done <- StreamResult{Report: report, Err: fmt.Errorf("parser: %d error(s) found in strict mode", len(report.Errors))}
+			return
+		}
+		done <- StreamResult{Report: report}
+	}()
+
+	return events, done
+}
+
+// parseStreamLine classifies one line against an explicit "current
+// paragraph" value instead of a novel's paras slice, so it can run
+// without the whole novel in memory. It returns a *ParseError instead of
+// logging directly, so ParseStream can collect it into the report and,
+// with --strict, fail once the scan is done.
+func (p *Parser) parseStreamLine(line string, current *novel.Para, lastUnknown bool, lineNum int) (perr *ParseError, head *novel.NovelHead, newPara *novel.Para) {
+	if len(line) == 0 {
+		// A blank line is just a paragraph separator, not malformed
+		// input: it must neither be reported as an error nor count as
+		// "unknown" for the lastUnknown check below.
+		return nil, nil, nil
+	}
+
+	runeLine := []rune(line)
+
+	switch runeLine[0] {
+	case '《':
+		if m := p.title.FindStringSubmatch(line); m != nil {
+			names := p.title.SubexpNames()
+			var title, author string
+			for i, name := range names {
+				switch name {
+				case "title":
+					title = m[i]
+				case "author":
+					author = m[i]
+				}
+			}
+			return nil, &novel.NovelHead{Title: title, Author: author}, nil
+		}
+		return &ParseError{Line: lineNum, Column: 1, Kind: UnmatchedQuote, Snippet: line}, nil, nil
+	case ' ', '　':
+		if lastUnknown {
+			return &ParseError{Line: lineNum, Column: 1, Kind: UnknownLine, Snippet: line}, nil, nil
+		}
+		i := 0
+		for ; i < len(runeLine) && (runeLine[i] == ' ' || runeLine[i] == '\t' || runeLine[i] == '　'); i++ {
+		}
+		if i == len(runeLine) {
+			return &ParseError{Line: lineNum, Column: 1, Kind: EmptyContent, Snippet: line}, nil, nil
+		}
+		if current == nil {
+			return &ParseError{Line: lineNum, Column: i + 1, Kind: OrphanContent, Snippet: line}, nil, nil
+		}
+		current.Lines = append(current.Lines, string(runeLine[i:]))
+		return nil, nil, nil
+	default:
+		if h, ok := p.matcher.Match(line); ok {
+			return nil, nil, &novel.Para{ParaHead: novel.ParaHead{ID: h.ID, Title: h.Title, Kind: h.Kind.NovelKind()}}
+		}
+		return &ParseError{Line: lineNum, Column: 1, Kind: UnknownLine, Snippet: line}, nil, nil
+	}
+}
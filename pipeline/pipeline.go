@@ -0,0 +1,136 @@
+// Package pipeline turns a parser.Parser's output into Epub sections
+// using a classic scanner/worker-pool/writer arrangement: the scanner
+// (parser.Parser.ParseStream) emits paragraphs as they're found, a pool
+// of workers renders each one's HTML body in parallel, and a single
+// writer goroutine collects the results and sorts them with the same
+// novel.ParaHead.Less render.EpubRenderer's non-streaming sort.Stable
+// uses, so the streaming path orders sections identically.
+package pipeline
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/bmaupin/go-epub"
+
+	"github.com/chfanghr/makeepub/novel"
+	"github.com/chfanghr/makeepub/parser"
+)
+
+// Render converts a Para's lines into the HTML body of an EPUB section.
+// Kept separate from the worker loop so future rendering features
+// (Markdown/BBCode conversion, footnote linking) have a single place to
+// live without touching the scheduling code below.
+func Render(p novel.Para) string {
+	body := "<h1>" + html.EscapeString(p.ParaHead.String()) + "</h1>\n<p></p>\n"
+	for _, line := range p.Lines {
+		body += "<p>" + html.EscapeString(line) + "</p>\n"
+	}
+	return body
+}
+
+type renderedPara struct {
+	head novel.ParaHead
+	body string
+}
+
+// renderedParas defers to ParaHead.Less, the same ordering novel.Paras
+// uses, so a sort here picks the same order render.EpubRenderer would.
+type renderedParas []renderedPara
+
+func (rs renderedParas) Len() int           { return len(rs) }
+func (rs renderedParas) Less(i, j int) bool { return rs[i].head.Less(rs[j].head) }
+func (rs renderedParas) Swap(i, j int)      { rs[i], rs[j] = rs[j], rs[i] }
+
+// Pipeline renders a novel's paragraphs to EPUB sections concurrently.
+type Pipeline struct {
+	// Workers is the size of the render worker pool.
+	Workers int
+}
+
+// New returns a Pipeline with the given worker pool size, clamped to at
+// least 1.
+func New(workers int) *Pipeline {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pipeline{Workers: workers}
+}
+
+// Run drains r through p, rendering each chapter across pl.Workers
+// goroutines and adding the results to e in chapter order. It returns
+// the novel's title/author and the parser's report once the stream is
+// exhausted.
+func (pl *Pipeline) Run(p *parser.Parser, r io.Reader, e *epub.Epub) (*novel.NovelHead, *parser.ParseReport, error) {
+	events, done := p.ParseStream(r)
+
+	jobs := make(chan novel.Para)
+	results := make(chan renderedPara)
+
+	var workersWG sync.WaitGroup
+	workersWG.Add(pl.Workers)
+	for i := 0; i < pl.Workers; i++ {
+		go func() {
+			defer workersWG.Done()
+			for para := range jobs {
+				results <- renderedPara{head: para.ParaHead, body: Render(para)}
+			}
+		}()
+	}
+	go func() {
+		workersWG.Wait()
+		close(results)
+	}()
+
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- writeSections(e, results)
+	}()
+
+	var head novel.NovelHead
+	for ev := range events {
+		if ev.Head != nil {
+			head = *ev.Head
+		}
+		if ev.Para != nil {
+			jobs <- *ev.Para
+		}
+	}
+	close(jobs)
+
+	result := <-done
+
+	if err := <-writeErr; err != nil {
+		return nil, result.Report, err
+	}
+	if result.Err != nil {
+		return nil, result.Report, result.Err
+	}
+
+	return &head, result.Report, nil
+}
+
+// writeSections collects every rendered section from results (they can
+// arrive in any order, since workers finish concurrently), stably sorts
+// them by ParaHead.Less — falling back to scan-order Seq to keep
+// unnumbered markers like two "番外" side stories deterministic — and
+// only then calls e.AddSection, so the streaming path's output order
+// matches the non-streaming render.EpubRenderer exactly.
+func writeSections(e *epub.Epub, results <-chan renderedPara) error {
+	var all renderedParas
+	for r := range results {
+		all = append(all, r)
+	}
+	sort.Stable(all)
+
+	for _, item := range all {
+		if _, err := e.AddSection(item.body, item.head.String(), "", ""); err != nil {
+			return fmt.Errorf("pipeline: adding section %q: %w", item.head.String(), err)
+		}
+	}
+
+	return nil
+}
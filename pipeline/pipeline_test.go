@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bmaupin/go-epub"
+
+	"github.com/chfanghr/makeepub/parser"
+)
+
+// syntheticNovel builds a source text with n chapters of a few lines
+// each, enough to make rendering cost (not just I/O) visible in the
+// benchmark below.
+func syntheticNovel(chapters int) string {
+	var b strings.Builder
+	b.WriteString("《基准测试》 - 作者：测试\n")
+	for i := 1; i <= chapters; i++ {
+		fmt.Fprintf(&b, "第%d章 标题%d\n", i, i)
+		for l := 0; l < 20; l++ {
+			fmt.Fprintf(&b, "　　这是第%d章的第%d行内容，用于模拟真实小说的段落长度。\n", i, l)
+		}
+	}
+	return b.String()
+}
+
+func TestPipelineRunReturnsNovelHead(t *testing.T) {
+	src := syntheticNovel(50)
+	p, err := parser.New(parser.Builtins[parser.DefaultGrammarName])
+	if err != nil {
+		t.Fatalf("parser.New: %v", err)
+	}
+
+	e := epub.NewEpub("test")
+	head, _, err := New(4).Run(p, strings.NewReader(src), e)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if head.Title != "基准测试" {
+		t.Fatalf("unexpected title: %q", head.Title)
+	}
+}
+
+// BenchmarkRun demonstrates the throughput gain from rendering chapters
+// across a worker pool instead of one at a time. Run with e.g.
+// `go test -bench Run -cpu 1,2,4,8 ./pipeline` to compare.
+func BenchmarkRun(b *testing.B) {
+	src := syntheticNovel(2000)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			p, err := parser.New(parser.Builtins[parser.DefaultGrammarName])
+			if err != nil {
+				b.Fatalf("parser.New: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				e := epub.NewEpub("bench")
+				if _, _, err := New(workers).Run(p, strings.NewReader(src), e); err != nil {
+					b.Fatalf("Run: %v", err)
+				}
+			}
+		})
+	}
+}
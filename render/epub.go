@@ -0,0 +1,38 @@
+package render
+
+import (
+	"sort"
+
+	"github.com/bmaupin/go-epub"
+
+	"github.com/chfanghr/makeepub/novel"
+	"github.com/chfanghr/makeepub/pipeline"
+)
+
+// EpubRenderer writes n as a standard EPUB via go-epub. This is the
+// pre-existing behavior of the tool, now reachable as one of several
+// --format targets instead of the only one.
+type EpubRenderer struct{}
+
+func (EpubRenderer) Render(n *novel.Novel, out string, opts Options) error {
+	sort.Stable(n.Paras)
+
+	e := epub.NewEpub(n.Title)
+	e.SetAuthor(n.Author)
+
+	if opts.Cover != "" {
+		coverPath, err := e.AddImage(opts.Cover, "")
+		if err != nil {
+			return err
+		}
+		e.SetCover(coverPath, "")
+	}
+
+	for _, para := range n.Paras {
+		if _, err := e.AddSection(pipeline.Render(para), para.ParaHead.String(), "", ""); err != nil {
+			return err
+		}
+	}
+
+	return e.Write(out + ".epub")
+}
@@ -0,0 +1,167 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/chfanghr/makeepub/novel"
+	"github.com/chfanghr/makeepub/pipeline"
+)
+
+// MobiRenderer writes n as a Mobi/AZW3 file. If kindlegen is on PATH it
+// is invoked directly; otherwise MobiRenderer falls back to emitting the
+// OPF, NCX and per-chapter XHTML that kindlegen expects, under
+// out+".mobi.src/", so the result can be converted elsewhere.
+type MobiRenderer struct{}
+
+func (MobiRenderer) Render(n *novel.Novel, out string, opts Options) error {
+	sort.Stable(n.Paras)
+
+	srcDir := out + ".mobi.src"
+	if err := os.MkdirAll(srcDir, 0o755); err != nil {
+		return fmt.Errorf("render: mobi: %w", err)
+	}
+
+	if opts.Font != "" {
+		fontsDir := filepath.Join(srcDir, "fonts")
+		if err := os.MkdirAll(fontsDir, 0o755); err != nil {
+			return fmt.Errorf("render: mobi: %w", err)
+		}
+		if err := copyFile(opts.Font, filepath.Join(fontsDir, filepath.Base(opts.Font))); err != nil {
+			return fmt.Errorf("render: mobi: embedding font: %w", err)
+		}
+	}
+
+	if opts.Cover != "" {
+		if err := copyFile(opts.Cover, filepath.Join(srcDir, filepath.Base(opts.Cover))); err != nil {
+			return fmt.Errorf("render: mobi: embedding cover: %w", err)
+		}
+	}
+
+	manifest := make([]string, 0, len(n.Paras))
+	for i, para := range n.Paras {
+		name := fmt.Sprintf("chapter%04d.xhtml", i)
+		body := pipeline.Render(para)
+		if opts.Font != "" {
+			body = `<link rel="stylesheet" type="text/css" href="fonts.css"/>` + "\n" + body
+		}
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(xhtmlDocument(para.ParaHead.String(), body)), 0o644); err != nil {
+			return fmt.Errorf("render: mobi: writing %s: %w", name, err)
+		}
+		manifest = append(manifest, name)
+	}
+
+	if opts.Font != "" {
+		css := fmt.Sprintf("@font-face { font-family: \"embedded\"; src: url(\"fonts/%s\"); }\nbody { font-family: \"embedded\", serif; }\n", filepath.Base(opts.Font))
+		if err := os.WriteFile(filepath.Join(srcDir, "fonts.css"), []byte(css), 0o644); err != nil {
+			return fmt.Errorf("render: mobi: writing fonts.css: %w", err)
+		}
+	}
+
+	opfPath := filepath.Join(srcDir, "book.opf")
+	if err := os.WriteFile(opfPath, []byte(opfDocument(n, manifest, opts)), 0o644); err != nil {
+		return fmt.Errorf("render: mobi: writing opf: %w", err)
+	}
+	ncxPath := filepath.Join(srcDir, "toc.ncx")
+	if err := os.WriteFile(ncxPath, []byte(ncxDocument(n)), 0o644); err != nil {
+		return fmt.Errorf("render: mobi: writing ncx: %w", err)
+	}
+
+	if kindlegen, err := exec.LookPath("kindlegen"); err == nil {
+		cmd := exec.Command(kindlegen, filepath.Base(opfPath), "-o", filepath.Base(out)+".azw3")
+		cmd.Dir = srcDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("render: mobi: kindlegen: %w: %s", err, output)
+		}
+		return os.Rename(filepath.Join(srcDir, filepath.Base(out)+".azw3"), out+".azw3")
+	}
+
+	return nil
+}
+
+func xhtmlDocument(title, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>` + html.EscapeString(title) + `</title></head>
+<body>` + body + `</body>
+</html>
+`
+}
+
+func opfDocument(n *novel.Novel, manifest []string, opts Options) string {
+	items := ""
+	spine := ""
+	for i, name := range manifest {
+		id := fmt.Sprintf("item%d", i)
+		items += fmt.Sprintf(`    <item id="%s" href="%s" media-type="application/xhtml+xml"/>`+"\n", id, name)
+		spine += fmt.Sprintf(`    <itemref idref="%s"/>`+"\n", id)
+	}
+
+	guide := ""
+	if opts.Cover != "" {
+		items += fmt.Sprintf(`    <item id="cover-image" href="%s" media-type="%s"/>`+"\n", filepath.Base(opts.Cover), coverMediaType(opts.Cover))
+		guide = fmt.Sprintf(`  <guide>
+    <reference type="cover" title="Cover" href="%s"/>
+  </guide>
+`, filepath.Base(opts.Cover))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" unique-identifier="BookId" version="2.0">
+  <metadata>
+    <dc:title xmlns:dc="http://purl.org/dc/elements/1.1/">%s</dc:title>
+    <dc:creator xmlns:dc="http://purl.org/dc/elements/1.1/">%s</dc:creator>
+  </metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+%s  </manifest>
+  <spine toc="ncx">
+%s  </spine>
+%s</package>
+`, html.EscapeString(n.Title), html.EscapeString(n.Author), items, spine, guide)
+}
+
+func ncxDocument(n *novel.Novel) string {
+	points := ""
+	for i, para := range n.Paras {
+		points += fmt.Sprintf(`    <navPoint id="np%d" playOrder="%d">
+      <navLabel><text>%s</text></navLabel>
+      <content src="chapter%04d.xhtml"/>
+    </navPoint>
+`, i, i+1, html.EscapeString(para.ParaHead.String()), i)
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head/>
+  <docTitle><text>%s</text></docTitle>
+  <navMap>
+%s  </navMap>
+</ncx>
+`, html.EscapeString(n.Title), points)
+}
+
+// coverMediaType guesses the manifest media-type for a cover image from
+// its extension, defaulting to JPEG since that's what most covers are.
+func coverMediaType(path string) string {
+	switch filepath.Ext(path) {
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	default:
+		return "image/jpeg"
+	}
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
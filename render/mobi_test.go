@@ -0,0 +1,64 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chfanghr/makeepub/novel"
+)
+
+func TestMobiRendererWritesManifestWithoutKindlegen(t *testing.T) {
+	n := &novel.Novel{
+		NovelHead: novel.NovelHead{Title: "测试", Author: "张三"},
+		Paras: novel.Paras{
+			{ParaHead: novel.ParaHead{ID: 1, Title: "开始"}, Lines: []string{"第一行"}},
+		},
+	}
+
+	out := filepath.Join(t.TempDir(), "book")
+	if err := (MobiRenderer{}).Render(n, out, Options{}); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	srcDir := out + ".mobi.src"
+	for _, name := range []string{"book.opf", "toc.ncx", "chapter0000.xhtml"} {
+		if _, err := os.Stat(filepath.Join(srcDir, name)); err != nil {
+			t.Errorf("expected %s to exist: %v", name, err)
+		}
+	}
+}
+
+func TestMobiRendererCopiesCoverIntoManifest(t *testing.T) {
+	n := &novel.Novel{
+		NovelHead: novel.NovelHead{Title: "测试", Author: "张三"},
+		Paras: novel.Paras{
+			{ParaHead: novel.ParaHead{ID: 1, Title: "开始"}, Lines: []string{"第一行"}},
+		},
+	}
+
+	dir := t.TempDir()
+	cover := filepath.Join(dir, "cover.jpg")
+	if err := os.WriteFile(cover, []byte("fake jpeg"), 0o644); err != nil {
+		t.Fatalf("writing cover fixture: %v", err)
+	}
+
+	out := filepath.Join(dir, "book")
+	if err := (MobiRenderer{}).Render(n, out, Options{Cover: cover}); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	srcDir := out + ".mobi.src"
+	if _, err := os.Stat(filepath.Join(srcDir, "cover.jpg")); err != nil {
+		t.Errorf("expected cover.jpg to be copied into srcDir: %v", err)
+	}
+
+	opf, err := os.ReadFile(filepath.Join(srcDir, "book.opf"))
+	if err != nil {
+		t.Fatalf("reading book.opf: %v", err)
+	}
+	if !strings.Contains(string(opf), `<item id="cover-image" href="cover.jpg" media-type="image/jpeg"/>`) {
+		t.Errorf("expected book.opf manifest to reference cover.jpg, got:\n%s", opf)
+	}
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/chfanghr/makeepub/novel"
+	"github.com/chfanghr/makeepub/pipeline"
+)
+
+// PDFRenderer writes n as a single PDF by assembling one HTML file and
+// shelling out to wkhtmltopdf, which must be on PATH.
+type PDFRenderer struct{}
+
+func (PDFRenderer) Render(n *novel.Novel, out string, opts Options) error {
+	sort.Stable(n.Paras)
+
+	wkhtmltopdf, err := exec.LookPath("wkhtmltopdf")
+	if err != nil {
+		return fmt.Errorf("render: pdf: wkhtmltopdf not found on PATH: %w", err)
+	}
+
+	htmlPath := out + ".pdf.src.html"
+	if err := os.WriteFile(htmlPath, []byte(pdfHTMLDocument(n)), 0o644); err != nil {
+		return fmt.Errorf("render: pdf: %w", err)
+	}
+	defer os.Remove(htmlPath)
+
+	args := []string{}
+	if opts.Cover != "" {
+		args = append(args, "cover", opts.Cover)
+	}
+	args = append(args, htmlPath, out+".pdf")
+
+	cmd := exec.Command(wkhtmltopdf, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("render: pdf: wkhtmltopdf: %w: %s", err, output)
+	}
+
+	return nil
+}
+
+func pdfHTMLDocument(n *novel.Novel) string {
+	doc := `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"/><title>` + html.EscapeString(n.Title) + `</title></head>
+<body>
+`
+	for _, para := range n.Paras {
+		doc += pipeline.Render(para)
+	}
+	doc += `</body>
+</html>
+`
+	return doc
+}
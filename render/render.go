@@ -0,0 +1,30 @@
+// Package render turns a fully-parsed novel.Novel into on-disk book
+// files. Each output format implements Renderer, so the CLI's
+// --format epub,mobi,pdf fans a single novel out to several files in one
+// run instead of parsing the source once per format.
+package render
+
+import "github.com/chfanghr/makeepub/novel"
+
+// Options carries renderer inputs that aren't part of the novel itself.
+// Both fields are optional; a zero Options is valid.
+type Options struct {
+	// Cover is a path to a cover image.
+	Cover string
+	// Font is a path to a TrueType font to embed, for CJK sources whose
+	// target reader has no matching system font installed.
+	Font string
+}
+
+// Renderer writes n to a file named out plus the renderer's own
+// extension (e.g. out+".epub").
+type Renderer interface {
+	Render(n *novel.Novel, out string, opts Options) error
+}
+
+// Renderers maps a --format value to its Renderer.
+var Renderers = map[string]Renderer{
+	"epub": EpubRenderer{},
+	"mobi": MobiRenderer{},
+	"pdf":  PDFRenderer{},
+}